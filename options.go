@@ -0,0 +1,80 @@
+package progress_reporter
+
+import (
+	"io"
+	"time"
+)
+
+// Option 用于在创建 ProgressBar 时定制其行为，配合 NewProgressBarWithOptions 使用。
+type Option func(*ProgressBar)
+
+// NewProgressBarWithOptions 创建一个新的 ProgressBar 实例，并依次应用给定的 Option。
+// 当 total<=0 时，进度条在创建时即进入不定长模式，用指示器（spinner）代替百分比展示进度，
+// 适用于调用方事先不知道总工作量的场景。
+func NewProgressBarWithOptions(description string, total int, barLength int, opts ...Option) *ProgressBar {
+	pb := &ProgressBar{
+		total:          total,
+		barLength:      barLength,
+		startTime:      time.Now(),
+		description:    description,
+		theme:          defaultTheme,
+		renderer:       barRenderer{},
+		spinnerFrames:  defaultSpinnerFrames,
+		indeterminate:  total <= 0,
+		speedWindowMin: defaultSpeedWindowMin,
+		speedWindowMax: defaultSpeedWindowMax,
+	}
+
+	for _, opt := range opts {
+		opt(pb)
+	}
+
+	return pb
+}
+
+// OptionSetWriter 指定进度条输出写入的目标，默认是 os.Stdout。
+// 测试代码可以注入 bytes.Buffer 等来断言输出内容，而不必劫持 os.Stdout；
+// 多个 ProgressBar 也可以借此各自写向不同的 sink 并发渲染。
+func OptionSetWriter(w io.Writer) Option {
+	return func(pb *ProgressBar) {
+		pb.writer = w
+	}
+}
+
+// OptionTheme 自定义进度条绘制使用的字符主题（填充、空白、光标、左右边界）。
+func OptionTheme(theme Theme) Option {
+	return func(pb *ProgressBar) {
+		pb.theme = theme
+	}
+}
+
+// OptionRenderer 替换默认的 Renderer 实现，用于输出与默认进度条格式不同的自定义渲染结果。
+func OptionRenderer(r Renderer) Option {
+	return func(pb *ProgressBar) {
+		pb.renderer = r
+	}
+}
+
+// OptionShowBytes 启用后，current/total 会按人类可读的字节单位（如 "1.2 MiB"）展示，
+// 适用于跟踪下载、上传等以字节为单位的任务。
+func OptionShowBytes(show bool) Option {
+	return func(pb *ProgressBar) {
+		pb.showBytes = show
+	}
+}
+
+// OptionClearOnFinish 启用后，Finish/Exit 会清空进度条所在的行，而不是保留最终状态并换行。
+func OptionClearOnFinish(clear bool) Option {
+	return func(pb *ProgressBar) {
+		pb.clearOnFinish = clear
+	}
+}
+
+// OptionSpinner 自定义不定长模式下循环展示的指示器帧，仅在 total<=0 时生效。
+func OptionSpinner(frames []string) Option {
+	return func(pb *ProgressBar) {
+		if len(frames) > 0 {
+			pb.spinnerFrames = frames
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package progress_reporter
+
+import "testing"
+
+func TestAddTotalClearsIndeterminateOnceTotalIsKnown(t *testing.T) {
+	pb := NewProgressBarWithOptions("Dynamic Total", 0, 10)
+
+	if !pb.indeterminate {
+		t.Fatalf("expected a ProgressBar created with total<=0 to start indeterminate")
+	}
+
+	pb.AddTotal(5)
+
+	if pb.indeterminate {
+		t.Errorf("expected indeterminate to be false once AddTotal makes total > 0")
+	}
+	if pb.total != 5 {
+		t.Errorf("expected total 5, got %d", pb.total)
+	}
+}
@@ -0,0 +1,133 @@
+package progress_reporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Pool 把多个 *ProgressBar 堆叠渲染在同一个终端里：每次刷新先用 ANSI 光标上移
+// 转义序列（"\x1b[<n>A"）回到上一次重绘的起始行，再整体重新输出所有进度条，
+// 从而避免各个 ProgressBar 各自的 \r 写入相互交错、破坏彼此的显示。
+// 适用于例如每个下载文件一条进度条、每个流水线阶段一条进度条的场景。
+type Pool struct {
+	mu           sync.Mutex
+	bars         []*ProgressBar
+	writer       io.Writer
+	refreshRate  time.Duration
+	running      bool
+	stopCh       chan struct{}
+	linesWritten int // 上一次重绘打印的行数，决定下一次需要上移多少行
+}
+
+// NewPool 创建一个空的 Pool，默认输出到 os.Stdout，刷新间隔为 defaultRefreshRate。
+func NewPool() *Pool {
+	return &Pool{
+		writer:      os.Stdout,
+		refreshRate: defaultRefreshRate,
+	}
+}
+
+// SetWriter 指定 Pool 重绘时写入的目标，默认是 os.Stdout。
+func (p *Pool) SetWriter(w io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.writer = w
+}
+
+// SetRefreshRate 设置 Start 启动的后台重绘循环的间隔。
+// 需要在调用 Start 之前设置才能生效。
+func (p *Pool) SetRefreshRate(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refreshRate = d
+}
+
+// Add 把一个 ProgressBar 加入 Pool，使其跟随 Pool 的刷新节奏堆叠渲染，
+// 而不是各自向终端写入 \r 更新。加入之后，该 ProgressBar 自身的写出
+// （包括 OptionSetWriter 指定的 writer）都被丢弃，只由 Pool 的重绘输出。
+func (p *Pool) Add(pb *ProgressBar) {
+	pb.mu.Lock()
+	pb.writer = io.Discard
+	pb.mu.Unlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.bars = append(p.bars, pb)
+}
+
+// Start 启动一个后台 goroutine，按 refreshRate 周期性重绘 Pool 中的所有进度条。
+// 与 ProgressBar.Run 一样，Start 是幂等且非阻塞的。
+func (p *Pool) Start() {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = true
+	p.stopCh = make(chan struct{})
+	stopCh := p.stopCh
+	refreshRate := p.refreshRate
+	if refreshRate <= 0 {
+		refreshRate = defaultRefreshRate
+	}
+	p.mu.Unlock()
+
+	go p.refreshLoop(refreshRate, stopCh)
+}
+
+// refreshLoop 按 refreshRate 周期性调用 render，直到 stopCh 被关闭。
+func (p *Pool) refreshLoop(refreshRate time.Duration, stopCh chan struct{}) {
+	ticker := time.NewTicker(refreshRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.render()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Stop 停止后台重绘循环，刷新一次最终状态，并打印一个换行符。
+// 可以安全地多次调用。
+func (p *Pool) Stop() {
+	p.mu.Lock()
+	if p.running {
+		p.running = false
+		close(p.stopCh)
+	}
+	p.mu.Unlock()
+
+	p.render()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.writer)
+}
+
+// render 重绘 Pool 中所有进度条：先把光标上移到上一次重绘的起始行，
+// 再逐个输出每个 ProgressBar 当前状态对应的一行，并清除该行中残留的旧内容。
+func (p *Pool) render() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.linesWritten > 0 {
+		fmt.Fprintf(p.writer, "\x1b[%dA", p.linesWritten)
+	}
+
+	for _, pb := range p.bars {
+		line := strings.TrimPrefix(pb.renderLine(), "\r")
+		fmt.Fprintf(p.writer, "\r%s\x1b[K\n", line)
+	}
+
+	p.linesWritten = len(p.bars)
+}
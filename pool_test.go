@@ -0,0 +1,45 @@
+package progress_reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolAddStartStopRendersAllBars(t *testing.T) {
+	var buf bytes.Buffer
+
+	p := NewPool()
+	p.SetWriter(&buf)
+	p.SetRefreshRate(5 * time.Millisecond)
+
+	first := NewProgressBar("First", 10, 10)
+	second := NewProgressBar("Second", 10, 10)
+	p.Add(first)
+	p.Add(second)
+
+	p.Start()
+	time.Sleep(30 * time.Millisecond)
+	p.Stop()
+
+	output := buf.String()
+	if !strings.Contains(output, "First") || !strings.Contains(output, "Second") {
+		t.Fatalf("expected output to contain both bar descriptions, got %q", output)
+	}
+	if !strings.Contains(output, "\x1b[") {
+		t.Errorf("expected output to contain ANSI escape sequences for cursor movement, got %q", output)
+	}
+}
+
+func TestPoolStopIsSafeToCallMultipleTimes(t *testing.T) {
+	var buf bytes.Buffer
+
+	p := NewPool()
+	p.SetWriter(&buf)
+	p.Add(NewProgressBar("Only", 10, 10))
+
+	p.Start()
+	p.Stop()
+	p.Stop() // 第二次调用不应该 panic（例如重复 close 一个 channel）
+}
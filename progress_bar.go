@@ -1,12 +1,18 @@
 package progress_reporter
 
 import (
+	"container/list"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"sync"
 	"time"
 )
 
+// defaultRefreshRate 是 Run 在未调用 SetRefreshRate 时使用的后台刷新间隔。
+const defaultRefreshRate = 200 * time.Millisecond
+
 // ProgressBar 用于跟踪和显示任务的完成进度。
 type ProgressBar struct {
 	total            int        // 总工作单元数
@@ -16,6 +22,26 @@ type ProgressBar struct {
 	description      string     // 进度条的描述文字
 	currentStageName string     // 当前阶段名称，用于更细致的进度展示
 	mu               sync.Mutex // 用于保护并发访问
+
+	refreshRate   time.Duration // 后台自动刷新的时间间隔，由 SetRefreshRate 设置
+	running       bool          // Run 是否已经启动了后台刷新循环
+	stopCh        chan struct{} // 关闭该 channel 以通知刷新循环退出
+	alreadyOutput bool          // 是否已经向输出写入过内容，用于 Exit 判断是否需要换行
+	lastOutputLen int           // 最近一次写出内容的长度，供 OptionClearOnFinish 清行使用
+
+	writer        io.Writer // 进度条写入的目标，默认 os.Stdout
+	theme         Theme     // 进度条使用的字符主题
+	renderer      Renderer  // 负责把当前状态渲染成一行文本
+	showBytes     bool      // 是否把 current/total 展示为人类可读的字节单位
+	clearOnFinish bool      // Finish/Exit 时是否清空该行而不是保留并换行
+
+	indeterminate bool     // 创建时 total<=0，进入不定长（spinner）模式
+	spinnerFrames []string // 不定长模式下循环展示的指示器帧
+	spinnerIndex  int      // 下一次渲染要使用的指示器帧下标
+
+	speedWindowMin time.Duration // 速度估算可信所需的最短窗口时长，由 SetSpeedWindow 设置
+	speedWindowMax time.Duration // 速度历史保留的最长时间跨度，由 SetSpeedWindow 设置
+	speedSamples   *list.List    // 滑动窗口内的 (时间, current) 采样历史
 }
 
 // NewProgressBar 创建一个新的 ProgressBar 实例。
@@ -23,15 +49,7 @@ type ProgressBar struct {
 // total: 总工作单元数。
 // barLength: 进度条在控制台显示的字符长度。
 func NewProgressBar(description string, total int, barLength int) *ProgressBar {
-	return &ProgressBar{
-		total:            total,
-		current:          0,
-		barLength:        barLength,
-		startTime:        time.Now(),
-		description:      description,
-		currentStageName: "",
-		mu:               sync.Mutex{},
-	}
+	return NewProgressBarWithOptions(description, total, barLength)
 }
 
 // Increment 使已完成的工作单元数增加1，并刷新进度条显示。
@@ -46,16 +64,16 @@ func (pb *ProgressBar) IncrementBy(n int) {
 	defer pb.mu.Unlock()
 
 	if n < 0 {
-		fmt.Println("Error: Increment value cannot be negative.")
+		fmt.Fprintln(pb.outputLocked(), "Error: Increment value cannot be negative.")
 		return
 	}
 	pb.current += n
 	if pb.current > pb.total {
 		pb.current = pb.total // 防止当前进度超过总数
 	}
+	pb.recordSpeedSampleLocked()
 
-	pb.displayWithoutLock(pb.current, pb.total, pb.description, pb.barLength,
-		pb.startTime, pb.currentStageName) // 使用不带锁的显示方法
+	pb.renderIfNotRunningLocked() // Run 启动后台刷新循环时，渲染完全交给该循环
 }
 
 // AddTotal 动态增加总工作单元数。
@@ -68,14 +86,16 @@ func (pb *ProgressBar) AddTotal(n int) {
 	if pb.total < 0 {
 		pb.total = 0 // 总数不能为负
 	}
+	if pb.total > 0 {
+		pb.indeterminate = false // 一旦知道了总数，就退出不定长模式，改为正常展示百分比
+	}
 
 	// 如果当前进度超过了新的总数（例如，总数被减少了），则调整当前进度
 	if pb.current > pb.total {
 		pb.current = pb.total
 	}
 
-	pb.displayWithoutLock(pb.current, pb.total, pb.description, pb.barLength,
-		pb.startTime, pb.currentStageName) // 使用不带锁的显示方法
+	pb.renderIfNotRunningLocked() // Run 启动后台刷新循环时，渲染完全交给该循环
 }
 
 // SetCurrentStage 设置当前正在进行的阶段名称。
@@ -85,8 +105,7 @@ func (pb *ProgressBar) SetCurrentStage(name string) {
 	defer pb.mu.Unlock()
 
 	pb.currentStageName = name
-	pb.displayWithoutLock(pb.current, pb.total, pb.description, pb.barLength,
-		pb.startTime, pb.currentStageName) // 使用不带锁的显示方法
+	pb.renderIfNotRunningLocked() // Run 启动后台刷新循环时，渲染完全交给该循环
 }
 
 // Display 在控制台中打印当前的进度条状态。
@@ -96,41 +115,133 @@ func (pb *ProgressBar) Display() {
 	pb.mu.Lock()
 	defer pb.mu.Unlock()
 
-	// 使用公共的格式化函数
-	output := pb.formatProgressBar(pb.current, pb.total, pb.description, pb.barLength,
-		pb.startTime, pb.currentStageName)
-	fmt.Print(output)
-
-	if pb.current == pb.total {
-		fmt.Println() // 完成后换行
-	}
+	pb.displayWithoutLock(pb.current, pb.total, pb.description, pb.barLength,
+		pb.startTime, pb.currentStageName) // 使用不带锁的显示方法
 }
 
 // displayWithoutLock 在控制台中打印当前的进度条状态（不带锁）。
 func (pb *ProgressBar) displayWithoutLock(current, total int, description string, barLength int, startTime time.Time, currentStageName string) {
 	// 使用公共的格式化函数
 	output := pb.formatProgressBar(current, total, description, barLength, startTime, currentStageName)
-	fmt.Print(output)
+	pb.writeLineLocked(output)
+}
 
-	if current == total {
-		fmt.Println() // 完成后换行
+// renderIfNotRunningLocked 在 Run 的后台刷新循环未启动时同步渲染一次，
+// 否则只更新状态、把渲染完全交给刷新循环，避免每次调用都同步写出造成的锁竞争和刷屏。
+// 调用方必须持有 pb.mu。
+func (pb *ProgressBar) renderIfNotRunningLocked() {
+	if pb.running {
+		return
+	}
+	pb.displayWithoutLock(pb.current, pb.total, pb.description, pb.barLength,
+		pb.startTime, pb.currentStageName)
+}
+
+// outputLocked 返回进度条应写入的目标：OptionSetWriter 指定的 writer，
+// 或者（未指定时）当前的 os.Stdout，解析时机推迟到每次写出以兼容对 os.Stdout 的劫持。
+func (pb *ProgressBar) outputLocked() io.Writer {
+	if pb.writer != nil {
+		return pb.writer
 	}
+	return os.Stdout
 }
 
-// formatProgressBar 根据给定的参数格式化进度条字符串
-// 返回一个已格式化的字符串，包含进度条、百分比、计数等信息
+// writeLineLocked 把渲染好的一行写入输出目标，并在进度完成时换行或清行。
+// 调用方必须持有 pb.mu。
+func (pb *ProgressBar) writeLineLocked(output string) {
+	fmt.Fprint(pb.outputLocked(), output)
+	pb.alreadyOutput = true
+	pb.lastOutputLen = len(output)
+
+	if !pb.indeterminate && pb.total > 0 && pb.current == pb.total {
+		pb.finishLineLocked()
+	}
+}
+
+// finishLineLocked 结束当前行：默认换行，OptionClearOnFinish 时改为清空该行。
+// 调用方必须持有 pb.mu。
+func (pb *ProgressBar) finishLineLocked() {
+	if pb.clearOnFinish {
+		fmt.Fprintf(pb.outputLocked(), "\r%s\r", strings.Repeat(" ", pb.lastOutputLen))
+		return
+	}
+	fmt.Fprintln(pb.outputLocked())
+}
+
+// SetRefreshRate 设置 Run 启动的后台刷新循环的重绘间隔。
+// 需要在调用 Run 之前设置才能生效；若从未设置，Run 使用 defaultRefreshRate。
+func (pb *ProgressBar) SetRefreshRate(d time.Duration) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.refreshRate = d
+}
+
+// Run 启动一个后台 goroutine，按 SetRefreshRate 设定的间隔持续重绘进度条，
+// 从而不再需要调用方在每次 Increment 之后手动调用 Display。
+// 多次调用是幂等的：如果刷新循环已经在运行，再次调用不会产生效果。
+// Run 本身不会阻塞调用方。
+func (pb *ProgressBar) Run() {
+	pb.mu.Lock()
+	if pb.running {
+		pb.mu.Unlock()
+		return
+	}
+	pb.running = true
+	pb.stopCh = make(chan struct{})
+	stopCh := pb.stopCh
+	refreshRate := pb.refreshRate
+	if refreshRate <= 0 {
+		refreshRate = defaultRefreshRate
+	}
+	pb.mu.Unlock()
+
+	go pb.refreshLoop(refreshRate, stopCh)
+}
+
+// refreshLoop 按 refreshRate 周期性调用 Display，直到 stopCh 被关闭。
+func (pb *ProgressBar) refreshLoop(refreshRate time.Duration, stopCh chan struct{}) {
+	ticker := time.NewTicker(refreshRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pb.Display()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Exit 停止 Run 启动的后台刷新循环。如果期间确实有内容被输出过，
+// Exit 会按 OptionClearOnFinish 的设置换行或清空最后一行，
+// 避免后续输出与进度条尾部粘连。Exit 可以被安全地多次调用。
+func (pb *ProgressBar) Exit() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if !pb.running {
+		return
+	}
+	pb.running = false
+	close(pb.stopCh) // 关闭 channel 以广播的方式通知刷新循环退出
+
+	if pb.alreadyOutput {
+		pb.finishLineLocked()
+	}
+}
+
+// formatProgressBar 根据给定的参数计算速度、ETA 等派生状态，并委托给 pb.renderer 渲染成字符串。
 func (pb *ProgressBar) formatProgressBar(current, total int, description string, barLength int,
 	startTime time.Time, currentStageName string) string {
 	elapsedTime := time.Since(startTime)
-	elapsedSeconds := elapsedTime.Seconds()
 
 	avgSpeedString := "0.0 items/s"
 	var avgSpeed float64
-	if elapsedSeconds > 0 && current > 0 {
-		avgSpeed = float64(current) / elapsedSeconds
+	if speed, ok := pb.windowSpeedLocked(); ok {
+		avgSpeed = speed
 		avgSpeedString = fmt.Sprintf("%.1f items/s", avgSpeed)
-	} else if current == 0 && elapsedSeconds > 0 {
-		avgSpeedString = "0.0 items/s"
 	}
 
 	etaString := "N/A"
@@ -140,38 +251,40 @@ func (pb *ProgressBar) formatProgressBar(current, total int, description string,
 		remainingItems := total - current
 		etaSeconds := float64(remainingItems) / avgSpeed
 		etaString = (time.Duration(etaSeconds*1000) * time.Millisecond).Round(time.Second).String()
-	} else if current == 0 && total > 0 {
+	} else {
 		etaString = "Estimating..."
 	}
 
-	if total == 0 { // 防止除以零
-		return fmt.Sprintf("\r%s: [ %s ] %d%% (%d/%d) | Stage: %s | Elapsed: %s | Avg: %s | ETA: %s",
-			description,
-			strings.Repeat("-", barLength),
-			0,
-			current,
-			total,
-			currentStageName,
-			elapsedTime.Round(time.Second).String(),
-			avgSpeedString,
-			etaString)
-	}
-
-	percent := float64(current) / float64(total)
-	filledLength := int(float64(barLength) * percent)
-	bar := strings.Repeat("=", filledLength) + strings.Repeat("-", barLength-filledLength)
-
-	// 使用 \r 回车符将光标移到行首，实现动态更新效果
-	return fmt.Sprintf("\r%s: [%s] %3.0f%% (%d/%d) | Stage: %s | Elapsed: %s | Avg: %s | ETA: %s",
-		description,
-		bar,
-		percent*100,
-		current,
-		total,
-		currentStageName,
-		elapsedTime.Round(time.Second).String(),
-		avgSpeedString,
-		etaString)
+	state := RenderState{
+		Current:          current,
+		Total:            total,
+		Description:      description,
+		BarLength:        barLength,
+		Elapsed:          elapsedTime.Round(time.Second),
+		CurrentStageName: currentStageName,
+		AvgSpeedString:   avgSpeedString,
+		ETA:              etaString,
+		Theme:            pb.theme,
+		ShowBytes:        pb.showBytes,
+		Indeterminate:    pb.indeterminate,
+	}
+
+	if pb.indeterminate {
+		state.SpinnerFrame = pb.spinnerFrames[pb.spinnerIndex%len(pb.spinnerFrames)]
+		pb.spinnerIndex++
+	}
+
+	return pb.renderer.Render(state)
+}
+
+// renderLine 返回当前状态对应的一行文本，但不写入任何输出目标。
+// 供 Pool 统一重绘多个 ProgressBar 时使用。
+func (pb *ProgressBar) renderLine() string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	return pb.formatProgressBar(pb.current, pb.total, pb.description, pb.barLength,
+		pb.startTime, pb.currentStageName)
 }
 
 // Finish 标记进度条完成，并打印最终状态。
@@ -181,7 +294,10 @@ func (pb *ProgressBar) Finish() {
 
 	pb.current = pb.total // 确保进度为100%
 	pb.currentStageName = "完成"
-	pb.displayWithoutLock(pb.current, pb.total, pb.description, pb.barLength,
-		pb.startTime, pb.currentStageName) // 使用不带锁的显示方法
-	fmt.Println() // 确保在完成后换行
+	output := pb.formatProgressBar(pb.current, pb.total, pb.description, pb.barLength,
+		pb.startTime, pb.currentStageName)
+	fmt.Fprint(pb.outputLocked(), output)
+	pb.alreadyOutput = true
+	pb.lastOutputLen = len(output)
+	pb.finishLineLocked()
 }
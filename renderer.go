@@ -0,0 +1,119 @@
+package progress_reporter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RenderState 是某一时刻进度条状态的只读快照，formatProgressBar 据此构造，
+// 再交给 Renderer 生成最终要写出的一行文本。
+type RenderState struct {
+	Current          int           // 当前已完成的工作单元数
+	Total            int           // 总工作单元数
+	Description      string        // 进度条的描述文字
+	BarLength        int           // 进度条在控制台中显示的长度
+	Elapsed          time.Duration // 自进度条创建以来经过的时间
+	CurrentStageName string        // 当前阶段名称
+	AvgSpeedString   string        // 已经格式化好的平均速度文字，例如 "1.5 items/s"
+	ETA              string        // 已经格式化好的预计剩余时间文字
+	Theme            Theme         // 绘制进度条使用的字符主题
+	ShowBytes        bool          // Current/Total 是否要展示为人类可读的字节单位
+	Indeterminate    bool          // 是否处于不定长（total<=0）模式
+	SpinnerFrame     string        // Indeterminate 为 true 时，本次渲染使用的指示器帧
+}
+
+// Renderer 负责把一份 RenderState 渲染成可以直接写入终端的一行文本。
+// 默认实现是 barRenderer；调用方可以通过 OptionRenderer 替换为自定义实现，
+// 例如输出纯文本日志而不是带 \r 的动态进度条。
+type Renderer interface {
+	Render(state RenderState) string
+}
+
+// barRenderer 是默认的 Renderer 实现，复现了进度条原有的 "[===>---] 25% (5/20)" 格式，
+// 并在 Indeterminate 模式下改为展示指示器帧。
+type barRenderer struct{}
+
+// Render 实现 Renderer 接口。
+func (barRenderer) Render(s RenderState) string {
+	if s.Indeterminate {
+		return fmt.Sprintf("\r%s %s: %s | Stage: %s | Elapsed: %s",
+			s.SpinnerFrame,
+			s.Description,
+			formatCount(s.Current, s.ShowBytes),
+			s.CurrentStageName,
+			s.Elapsed)
+	}
+
+	var filledLength int
+	var percent float64
+	if s.Total > 0 {
+		percent = float64(s.Current) / float64(s.Total)
+		filledLength = int(float64(s.BarLength) * percent)
+	}
+	bar := s.Theme.renderBar(filledLength, s.BarLength)
+
+	return fmt.Sprintf("\r%s: %s %3.0f%% (%s/%s) | Stage: %s | Elapsed: %s | Avg: %s | ETA: %s",
+		s.Description,
+		bar,
+		percent*100,
+		formatCount(s.Current, s.ShowBytes),
+		formatCount(s.Total, s.ShowBytes),
+		s.CurrentStageName,
+		s.Elapsed,
+		s.AvgSpeedString,
+		s.ETA)
+}
+
+// Theme 定义了进度条绘制时使用的字符集，配合 OptionTheme 定制外观。
+type Theme struct {
+	Filled   string // 已完成部分使用的字符，默认 "="
+	Empty    string // 未完成部分使用的字符，默认 "-"
+	Head     string // 已完成与未完成交界处的光标字符，默认 ">"
+	LeftCap  string // 进度条左边界，默认 "["
+	RightCap string // 进度条右边界，默认 "]"
+}
+
+// defaultTheme 是未通过 OptionTheme 定制时使用的默认字符集。
+var defaultTheme = Theme{Filled: "=", Empty: "-", Head: ">", LeftCap: "[", RightCap: "]"}
+
+// defaultSpinnerFrames 是未通过 OptionSpinner 定制时，不定长模式使用的指示器帧。
+var defaultSpinnerFrames = []string{"|", "/", "-", "\\"}
+
+// renderBar 按照当前主题绘制一段长度为 barLength、已填充 filledLength 格的进度条（含首尾边界）。
+func (t Theme) renderBar(filledLength, barLength int) string {
+	switch {
+	case filledLength <= 0:
+		return t.LeftCap + strings.Repeat(t.Empty, barLength) + t.RightCap
+	case filledLength >= barLength:
+		return t.LeftCap + strings.Repeat(t.Filled, barLength) + t.RightCap
+	default:
+		return t.LeftCap + strings.Repeat(t.Filled, filledLength-1) + t.Head +
+			strings.Repeat(t.Empty, barLength-filledLength) + t.RightCap
+	}
+}
+
+// formatCount 把一个计数值格式化为展示文本：默认是十进制整数，
+// OptionShowBytes(true) 时改为人类可读的字节单位，例如 "1.2 MiB"。
+func formatCount(n int, showBytes bool) string {
+	if !showBytes {
+		return strconv.Itoa(n)
+	}
+	return formatBytes(n)
+}
+
+// formatBytes 把字节数 n 格式化为类似 "1.2 MiB" 的人类可读字符串。
+func formatBytes(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for nn := int64(n) / unit; nn >= unit; nn /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
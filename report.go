@@ -0,0 +1,84 @@
+package progress_reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Format 指定 Report 输出的编码格式。
+type Format int
+
+const (
+	FormatText Format = iota // 人类可读的文本格式，沿用旧版 Report() 的行格式，额外带上 Fail 计数和 P50/P90/P99
+	FormatJSON               // 机器可读的 JSON，字段与 ProgressSnapshot 一致
+	FormatCSV                // 每个关键阶段一行的 CSV，便于导入表格或其它分析工具
+)
+
+// Report 把 ProgressReporter 当前的状态按 format 编码后写入 w，取代了旧版本
+// 硬编码打印到 stdout、且把耗时截断成整秒的 Report()。FormatJSON 和 FormatCSV 以纳秒输出全部耗时；
+// FormatText 中的总时长、最大和最小耗时仍以整秒显示。
+// 使用场景：在所有计时操作完成后调用，把性能数据写给日志文件、HTTP 响应或监控管道。
+func (p *ProgressReporter) Report(w io.Writer, format Format) error {
+	snapshot := p.Snapshot()
+
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(snapshot)
+	case FormatCSV:
+		return writeCSVReport(w, snapshot)
+	default:
+		writeTextReport(w, snapshot)
+		return nil
+	}
+}
+
+// writeTextReport 按旧版 Report() 的行格式打印，额外带上 Fail 计数和 P50/P90/P99。
+func writeTextReport(w io.Writer, snapshot ProgressSnapshot) {
+	fmt.Fprintf(w, "Total duration: %d s\n", snapshot.TotalDuration/time.Second)
+
+	for _, s := range snapshot.Stages {
+		fmt.Fprintf(w, "\t Key: %s, Count: %d, Fail: %d, Total duration: %d s, Max duration: %d s, Min duration: %d s, P50: %s, P90: %s, P99: %s\n",
+			s.Name, s.Count, s.FailCount,
+			s.TotalDuration/time.Second, s.MaxDuration/time.Second, s.MinDuration/time.Second,
+			s.P50, s.P90, s.P99)
+	}
+}
+
+// writeCSVReport 写出一行表头和每个关键阶段一行数据，耗时均以纳秒整数表示。
+func writeCSVReport(w io.Writer, snapshot ProgressSnapshot) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{
+		"name", "count", "fail_count",
+		"total_duration_ns", "max_duration_ns", "min_duration_ns", "avg_duration_ns",
+		"p50_ns", "p90_ns", "p99_ns",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range snapshot.Stages {
+		record := []string{
+			s.Name,
+			strconv.Itoa(s.Count),
+			strconv.Itoa(s.FailCount),
+			strconv.FormatInt(int64(s.TotalDuration), 10),
+			strconv.FormatInt(int64(s.MaxDuration), 10),
+			strconv.FormatInt(int64(s.MinDuration), 10),
+			strconv.FormatInt(int64(s.AvgDuration), 10),
+			strconv.FormatInt(int64(s.P50), 10),
+			strconv.FormatInt(int64(s.P90), 10),
+			strconv.FormatInt(int64(s.P99), 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
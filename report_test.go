@@ -0,0 +1,79 @@
+package progress_reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReportJSON(t *testing.T) {
+	pr := NewProgressReporter()
+	tok := pr.StartKeyStageRecord("load")
+	pr.EndKeyStageRecord(tok)
+
+	var buf bytes.Buffer
+	if err := pr.Report(&buf, FormatJSON); err != nil {
+		t.Fatalf("Report(FormatJSON) returned error: %v", err)
+	}
+
+	var snapshot ProgressSnapshot
+	if err := json.Unmarshal(buf.Bytes(), &snapshot); err != nil {
+		t.Fatalf("failed to unmarshal JSON report: %v", err)
+	}
+	if snapshot.SchemaVersion != reportSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", reportSchemaVersion, snapshot.SchemaVersion)
+	}
+	if len(snapshot.Stages) != 1 || snapshot.Stages[0].Name != "load" {
+		t.Fatalf("expected one stage named 'load', got %+v", snapshot.Stages)
+	}
+	if snapshot.Stages[0].Count != 1 {
+		t.Errorf("expected count 1, got %d", snapshot.Stages[0].Count)
+	}
+}
+
+func TestReportCSV(t *testing.T) {
+	pr := NewProgressReporter()
+	tok := pr.StartKeyStageRecord("fetch")
+	pr.EndKeyStageRecord(tok)
+	pr.RecordKeyStageFailure("fetch")
+
+	var buf bytes.Buffer
+	if err := pr.Report(&buf, FormatCSV); err != nil {
+		t.Fatalf("Report(FormatCSV) returned error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV report: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected header + 1 data row, got %d rows", len(records))
+	}
+	if records[1][0] != "fetch" {
+		t.Errorf("expected first data row for 'fetch', got %q", records[1][0])
+	}
+	if records[1][2] != "1" {
+		t.Errorf("expected fail_count column to be 1, got %q", records[1][2])
+	}
+}
+
+func TestReportText(t *testing.T) {
+	pr := NewProgressReporter()
+	tok := pr.StartKeyStageRecord("load")
+	pr.EndKeyStageRecord(tok)
+
+	var buf bytes.Buffer
+	if err := pr.Report(&buf, FormatText); err != nil {
+		t.Fatalf("Report(FormatText) returned error: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Total duration:") {
+		t.Errorf("expected text report to contain total duration, got %q", output)
+	}
+	if !strings.Contains(output, "Key: load") {
+		t.Errorf("expected text report to contain stage 'load', got %q", output)
+	}
+}
@@ -1,16 +1,20 @@
 package progress_reporter
 
 import (
-	"fmt"
+	"sort"
+	"sync"
 	"time"
 )
 
 // ProgressReporter 用于跟踪和报告代码段的执行时间。
-// 可用于性能分析，识别代码中的瓶颈。
+// 可用于性能分析，识别代码中的瓶颈。ProgressReporter 本身以及它持有的每个
+// keyStage 都是并发安全的，同一个关键阶段可以在多个 goroutine 上同时记录。
 type ProgressReporter struct {
-	startTime, endTime time.Time            // 记录整体开始和结束时间
-	totalDuration      time.Duration        // 记录整体持续时间
-	ks                 map[string]*keyStage // 存储不同关键阶段的耗时信息
+	startTime, endTime time.Time     // 记录整体开始和结束时间
+	totalDuration      time.Duration // 记录整体持续时间
+
+	mu sync.RWMutex         // 保护 ks 这个 map 本身（新增/查找关键阶段），以及上面的整体计时字段
+	ks map[string]*keyStage // 存储不同关键阶段的耗时信息
 }
 
 // NewProgressReporter 创建一个新的 ProgressReporter 实例。
@@ -26,6 +30,9 @@ func NewProgressReporter() *ProgressReporter {
 // 应在需要跟踪时间的代码段的开头调用。
 // 使用场景：例如，在一个复杂函数的入口处调用 StartRecord，以开始计时整个函数的执行。
 func (p *ProgressReporter) StartRecord() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.startTime = time.Now()
 }
 
@@ -33,66 +40,215 @@ func (p *ProgressReporter) StartRecord() {
 // 应在需要跟踪时间的代码段的末尾调用。
 // 使用场景：与 StartRecord 配对使用，在对应复杂函数的出口处调用 EndRecord，以结束计时并计算总耗时。
 func (p *ProgressReporter) EndRecord() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.endTime = time.Now()
 	p.totalDuration = p.endTime.Sub(p.startTime)
 }
 
-// StartKeyStageRecord 记录特定关键阶段的开始时间。
-// 如果同名关键阶段已存在，则更新其开始��间。
-// 如果是新的关键阶段，则初始化该阶段的信息。
+// StageToken 是 StartKeyStageRecord 返回的不透明句柄，记录了某一次调用的阶段名和起始时间，
+// 必须原样传给对应的 EndKeyStageRecord。这样同一个阶段被多个 goroutine 并发调用时，
+// 各自的起始时间互不覆盖——旧实现把 startTime 存在共享的 keyStage 字段上，并发下会相互踩踏。
+type StageToken struct {
+	name  string
+	start time.Time
+}
+
+// StartKeyStageRecord 标记一次关键阶段调用的开始，返回的 StageToken 要传给 EndKeyStageRecord
+// 来结束这次调用的计时。如果是第一次出现的阶段名，会先初始化该阶段的统计信息。
 // 使用场景：在一个长任务内部，标记某个具体子步骤的开始。例如，在数据处理流程中，标记“数据加载”阶段的开始。
 // name: 关键阶段的名称。
-func (p *ProgressReporter) StartKeyStageRecord(name string) {
+func (p *ProgressReporter) StartKeyStageRecord(name string) StageToken {
+	p.mu.Lock()
 	if _, ok := p.ks[name]; !ok {
-		p.ks[name] = &keyStage{
-			name:        name,
-			minDuration: time.Duration(1<<63 - 1), // 初始化为最大可能持续时间
-		}
+		p.ks[name] = newKeyStage(name)
 	}
+	p.mu.Unlock()
 
-	p.ks[name].startTime = time.Now()
+	return StageToken{name: name, start: time.Now()}
 }
 
-// EndKeyStageRecord 记录特定关键阶段的结束时间并更新其统计信息。
-// 计算当前阶段的持续时间，并更新总持续时间、计数、最大和最小持续时间。
+// EndKeyStageRecord 用 StartKeyStageRecord 返回的 token 结束一次关键阶段调用的计时，
+// 并把本次耗时计入该阶段的统计信息（总时长、次数、最大/最小值，以及用于百分位数的采样）。
 // 使用场景：与 StartKeyStageRecord 配对使用，标记某个具体子步骤的结束。例如，标记“数据加载”阶段的结束，并记录其耗时。
-// name: 关键阶段的名称。
-func (p *ProgressReporter) EndKeyStageRecord(name string) {
-	p.ks[name].endTime = time.Now()
-	currentDuration := p.ks[name].endTime.Sub(p.ks[name].startTime)
-	p.ks[name].totalDuration += currentDuration
-	p.ks[name].count++
-
-	if currentDuration > p.ks[name].maxDuration {
-		p.ks[name].maxDuration = currentDuration
+func (p *ProgressReporter) EndKeyStageRecord(tok StageToken) {
+	duration := time.Since(tok.start)
+
+	p.mu.RLock()
+	ks, ok := p.ks[tok.name]
+	p.mu.RUnlock()
+	if !ok {
+		return
 	}
-	if currentDuration < p.ks[name].minDuration {
-		p.ks[name].minDuration = currentDuration
+
+	ks.record(duration)
+}
+
+// RecordKeyStageFailure 标记某个关键阶段的一次调用失败，使其 failCount 加一。
+// 如果同名关键阶段不存在，则先初始化该阶段的信息，与 StartKeyStageRecord 行为一致。
+// 使用场景：例如 WorkerPool 中某个任务函数返回了 error，耗时仍然有效，但需要额外统计失败次数。
+// name: 关键阶段的名称。
+func (p *ProgressReporter) RecordKeyStageFailure(name string) {
+	p.mu.Lock()
+	if _, ok := p.ks[name]; !ok {
+		p.ks[name] = newKeyStage(name)
 	}
+	ks := p.ks[name]
+	p.mu.Unlock()
+
+	ks.recordFailure()
 }
 
-// Report 打印整体持续时间和每个关键阶段的详细统计信息。
-// 输出格式：
-// Total duration: X s
-//
-//	Key: stage_name, Count: Y, Total duration: Z s, Max duration: A s, Min duration: B s
-//
-// 使用场景：在所有计时操作完成后，调用此方法将性能数据输出到控制台或日志，以便分析。
-func (p *ProgressReporter) Report() {
-	// print total duration
-	fmt.Printf("Total duration: %d s\n", p.totalDuration/time.Second)
-
-	// print key stage
-	for k, v := range p.ks {
-		fmt.Printf("\t Key: %s, Count: %d, Total duration: %d s, Max duration: %d s, Min duration: %d s\n", k, v.count, v.totalDuration/time.Second, v.maxDuration/time.Second, v.minDuration/time.Second)
+// reportSchemaVersion 标识 ProgressSnapshot 的结构版本，供下游消费者判断兼容性。
+const reportSchemaVersion = 1
+
+// KeyStageSnapshot 是某个关键阶段耗时统计的无锁快照，可以安全地被序列化或跨协程传递。
+// 所有耗时字段都以 time.Duration（纳秒精度）存储，不做截断，避免快速阶段的耗时信息丢失。
+type KeyStageSnapshot struct {
+	Name          string        `json:"name"`
+	Count         int           `json:"count"`
+	FailCount     int           `json:"fail_count"`
+	TotalDuration time.Duration `json:"total_duration_ns"`
+	MaxDuration   time.Duration `json:"max_duration_ns"`
+	MinDuration   time.Duration `json:"min_duration_ns"`
+	AvgDuration   time.Duration `json:"avg_duration_ns"`
+	P50           time.Duration `json:"p50_ns"`
+	P90           time.Duration `json:"p90_ns"`
+	P99           time.Duration `json:"p99_ns"`
+}
+
+// ProgressSnapshot 是 ProgressReporter 某一时刻状态的无锁快照：纯值字段，不含锁或
+// time.Time 游标，可以安全地序列化后通过 HTTP/gRPC 发送，或者喂给 expvar 之类的监控系统。
+type ProgressSnapshot struct {
+	SchemaVersion int                `json:"schema_version"`
+	TotalDuration time.Duration      `json:"total_duration_ns"`
+	Stages        []KeyStageSnapshot `json:"stages"`
+}
+
+// Snapshot 返回 ProgressReporter 当前状态的一份 ProgressSnapshot。
+// 使用场景：需要把进度/性能数据交给外部系统（监控面板、RPC 响应）时，调用此方法
+// 得到一份普通数据，而不必把 ProgressReporter 本身（含锁）暴露出去。
+func (p *ProgressReporter) Snapshot() ProgressSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stages := make([]KeyStageSnapshot, 0, len(p.ks))
+	for name, ks := range p.ks {
+		stages = append(stages, ks.snapshotStage(name))
+	}
+	sort.Slice(stages, func(i, j int) bool { return stages[i].Name < stages[j].Name })
+
+	return ProgressSnapshot{
+		SchemaVersion: reportSchemaVersion,
+		TotalDuration: p.totalDuration,
+		Stages:        stages,
 	}
 }
 
-// keyStage 存储单个关键阶段的耗时统计信息。
+// keyStageSampleCap 是每个关键阶段保留的耗时采样数量上限，用于估算 p50/p90/p99。
+// 超出上限后按环形缓冲覆盖最旧的采样，而不是无限增长。
+const keyStageSampleCap = 1024
+
+// keyStage 存储单个关键阶段的耗时统计信息，并发下通过自身的 mu 保护。
 type keyStage struct {
-	name                     string        // 关键阶段的名称
-	totalDuration            time.Duration // 此关键阶段的总累积持续时间
-	count                    int           // 此关键阶段被记录的次数
-	maxDuration, minDuration time.Duration // 此关键阶段记录到的最大和最小单次持续时间
-	startTime, endTime       time.Time     // 用于计算单次关键阶段持续时间的开始和结束时间
+	name string
+
+	mu                       sync.Mutex
+	totalDuration            time.Duration   // 此关键阶段的总累积持续时间
+	count                    int             // 此关键阶段被记录的次数
+	failCount                int             // 此关键阶段被记录为失败的次数
+	maxDuration, minDuration time.Duration   // 此关键阶段记录到的最大和最小单次持续时间
+	samples                  []time.Duration // 有限容量的单次耗时采样，用于估算分位数
+	nextSample               int             // samples 写满之后，下一次覆盖写入的位置
+}
+
+// newKeyStage 创建一个新的 keyStage，minDuration 初始化为最大可能持续时间，
+// 以保证第一次记录的耗时总能刷新它。
+func newKeyStage(name string) *keyStage {
+	return &keyStage{
+		name:        name,
+		minDuration: time.Duration(1<<63 - 1),
+	}
+}
+
+// record 把一次耗时为 d 的调用计入统计信息。
+func (ks *keyStage) record(d time.Duration) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.totalDuration += d
+	ks.count++
+	if d > ks.maxDuration {
+		ks.maxDuration = d
+	}
+	if d < ks.minDuration {
+		ks.minDuration = d
+	}
+
+	if len(ks.samples) < keyStageSampleCap {
+		ks.samples = append(ks.samples, d)
+		return
+	}
+	ks.samples[ks.nextSample] = d
+	ks.nextSample = (ks.nextSample + 1) % keyStageSampleCap
+}
+
+// recordFailure 把 failCount 加一。
+func (ks *keyStage) recordFailure() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.failCount++
+}
+
+// snapshot 返回当前的 count、failCount、totalDuration、maxDuration、minDuration。
+func (ks *keyStage) snapshot() (count, failCount int, totalDuration, maxDuration, minDuration time.Duration) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	return ks.count, ks.failCount, ks.totalDuration, ks.maxDuration, ks.minDuration
+}
+
+// snapshotStage 把 keyStage 当前的统计信息整理成一份不含锁、可安全序列化的 KeyStageSnapshot。
+func (ks *keyStage) snapshotStage(name string) KeyStageSnapshot {
+	count, failCount, totalDuration, maxDuration, minDuration := ks.snapshot()
+	p50, p90, p99 := ks.percentiles()
+
+	var avgDuration time.Duration
+	if count > 0 {
+		avgDuration = totalDuration / time.Duration(count)
+	}
+
+	return KeyStageSnapshot{
+		Name:          name,
+		Count:         count,
+		FailCount:     failCount,
+		TotalDuration: totalDuration,
+		MaxDuration:   maxDuration,
+		MinDuration:   minDuration,
+		AvgDuration:   avgDuration,
+		P50:           p50,
+		P90:           p90,
+		P99:           p99,
+	}
+}
+
+// percentiles 基于保留的耗时采样，估算 p50/p90/p99。没有任何采样时返回全 0。
+func (ks *keyStage) percentiles() (p50, p90, p99 time.Duration) {
+	ks.mu.Lock()
+	sorted := make([]time.Duration, len(ks.samples))
+	copy(sorted, ks.samples)
+	ks.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) time.Duration {
+		idx := int(p * float64(len(sorted)-1))
+		return sorted[idx]
+	}
+	return percentile(0.5), percentile(0.9), percentile(0.99)
 }
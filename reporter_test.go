@@ -0,0 +1,104 @@
+package progress_reporter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStartKeyStageRecordAndEndKeyStageRecord(t *testing.T) {
+	pr := NewProgressReporter()
+
+	tok := pr.StartKeyStageRecord("load")
+	time.Sleep(time.Millisecond)
+	pr.EndKeyStageRecord(tok)
+
+	ks := pr.ks["load"]
+	if ks == nil {
+		t.Fatalf("expected key stage 'load' to be recorded")
+	}
+
+	count, failCount, totalDuration, _, _ := ks.snapshot()
+	if count != 1 {
+		t.Errorf("expected count 1, got %d", count)
+	}
+	if failCount != 0 {
+		t.Errorf("expected failCount 0, got %d", failCount)
+	}
+	if totalDuration <= 0 {
+		t.Errorf("expected totalDuration > 0, got %d", totalDuration)
+	}
+}
+
+func TestRecordKeyStageFailure(t *testing.T) {
+	pr := NewProgressReporter()
+
+	tok := pr.StartKeyStageRecord("fetch")
+	pr.EndKeyStageRecord(tok)
+	pr.RecordKeyStageFailure("fetch")
+
+	count, failCount, _, _, _ := pr.ks["fetch"].snapshot()
+	if count != 1 {
+		t.Errorf("expected count 1, got %d", count)
+	}
+	if failCount != 1 {
+		t.Errorf("expected failCount 1, got %d", failCount)
+	}
+}
+
+// TestProgressReporterConcurrentKeyStage 验证同一个关键阶段在多个 goroutine 上
+// 并发记录时，StageToken 让每次调用持有自己的起始时间，不会相互覆盖导致计数或耗时错乱。
+func TestProgressReporterConcurrentKeyStage(t *testing.T) {
+	pr := NewProgressReporter()
+
+	const goroutines = 50
+	const callsPerGoroutine = 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < callsPerGoroutine; j++ {
+				tok := pr.StartKeyStageRecord("concurrent-stage")
+				time.Sleep(time.Microsecond)
+				pr.EndKeyStageRecord(tok)
+			}
+		}()
+	}
+	wg.Wait()
+
+	count, _, _, _, _ := pr.ks["concurrent-stage"].snapshot()
+	expected := goroutines * callsPerGoroutine
+	if count != expected {
+		t.Errorf("expected count %d after concurrent recording, got %d", expected, count)
+	}
+
+	p50, p90, p99 := pr.ks["concurrent-stage"].percentiles()
+	if p50 <= 0 || p90 <= 0 || p99 <= 0 {
+		t.Errorf("expected positive percentiles, got p50=%s p90=%s p99=%s", p50, p90, p99)
+	}
+}
+
+// TestStartEndRecordConcurrentWithSnapshot 验证 StartRecord/EndRecord 写入的整体耗时
+// 字段和 Snapshot 读取的是同一把锁，并发调用不会被 -race 标记为数据竞争。
+func TestStartEndRecordConcurrentWithSnapshot(t *testing.T) {
+	pr := NewProgressReporter()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			pr.StartRecord()
+			pr.EndRecord()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			pr.Snapshot()
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,124 @@
+package progress_reporter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingWriter 统计 Write 被调用的次数，用于断言某个代码路径是否真的触发了写出。
+type countingWriter struct {
+	mu     sync.Mutex
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return len(p), nil
+}
+
+func (w *countingWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes
+}
+
+func TestRunSuppressesSynchronousRender(t *testing.T) {
+	w := &countingWriter{}
+	pb := NewProgressBarWithOptions("Run Test", 1000, 10, OptionSetWriter(w))
+	pb.SetRefreshRate(time.Hour) // 刷新循环本身这次测试期间不应触发，只验证同步写出被抑制
+
+	pb.Run()
+	defer pb.Exit()
+
+	for i := 0; i < 100; i++ {
+		pb.IncrementBy(1)
+	}
+
+	if got := w.count(); got != 0 {
+		t.Errorf("expected 0 synchronous writes while Run is active, got %d", got)
+	}
+}
+
+func TestDisplayWithoutRunStillRendersEveryCall(t *testing.T) {
+	w := &countingWriter{}
+	pb := NewProgressBarWithOptions("No Run Test", 1000, 10, OptionSetWriter(w))
+
+	for i := 0; i < 10; i++ {
+		pb.IncrementBy(1)
+	}
+
+	if got := w.count(); got != 10 {
+		t.Errorf("expected 10 synchronous writes without Run, got %d", got)
+	}
+}
+
+func TestRunIsIdempotent(t *testing.T) {
+	pb := NewProgressBar("Idempotent Run", 10, 10)
+
+	pb.Run()
+	pb.mu.Lock()
+	first := pb.stopCh
+	pb.mu.Unlock()
+
+	pb.Run() // 第二次调用应该是幂等的，不应该启动第二个刷新循环
+	pb.mu.Lock()
+	second := pb.stopCh
+	pb.mu.Unlock()
+
+	if first != second {
+		t.Errorf("expected a second Run call not to replace stopCh")
+	}
+
+	pb.Exit()
+}
+
+func TestExitIsSafeToCallMultipleTimes(t *testing.T) {
+	pb := NewProgressBar("Exit Twice", 10, 10)
+
+	pb.Run()
+	pb.Exit()
+
+	done := make(chan struct{})
+	go func() {
+		pb.Exit() // 第二次调用不应该 panic（例如重复 close 一个 channel）
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("second Exit call did not return in time")
+	}
+
+	pb.mu.Lock()
+	running := pb.running
+	pb.mu.Unlock()
+	if running {
+		t.Errorf("expected running to be false after Exit")
+	}
+}
+
+func TestRunThenExitStopsRefreshLoop(t *testing.T) {
+	w := &countingWriter{}
+	pb := NewProgressBarWithOptions("Refresh Loop", 10, 10, OptionSetWriter(w))
+	pb.SetRefreshRate(5 * time.Millisecond)
+
+	pb.Run()
+	time.Sleep(30 * time.Millisecond)
+	beforeExit := w.count()
+	pb.Exit()
+
+	// 给可能与 Exit 同时触发的最后一次 tick 一点时间落地，再断言计数已经稳定。
+	time.Sleep(10 * time.Millisecond)
+	stable := w.count()
+	time.Sleep(30 * time.Millisecond)
+	if got := w.count(); got != stable {
+		t.Errorf("expected no further writes once settled after Exit, had %d then %d", stable, got)
+	}
+	if beforeExit == 0 {
+		t.Errorf("expected the refresh loop to have rendered at least once before Exit")
+	}
+}
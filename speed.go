@@ -0,0 +1,118 @@
+package progress_reporter
+
+import (
+	"container/list"
+	"time"
+)
+
+// defaultSpeedWindowMin 是未调用 SetSpeedWindow 时，估算速度所需的最短窗口时长。
+// 窗口跨度小于这个值时，速度被认为还不可信，展示为 "Estimating..."。
+const defaultSpeedWindowMin = 1 * time.Second
+
+// defaultSpeedWindowMax 是未调用 SetSpeedWindow 时，速度历史保留的最长时间跨度。
+const defaultSpeedWindowMax = 30 * time.Second
+
+// minSpeedSampleInterval 是推算采样数量上限时假设的最小采样间隔。
+// 高频调用 IncrementBy 时，真实的采样间隔可能远小于这个值，
+// 因此仅靠 speedWindowMax 做按时间裁剪不足以限制内存占用，还需要下面的按数量裁剪兜底。
+const minSpeedSampleInterval = 10 * time.Millisecond
+
+// speedSampleCapFloor/speedSampleCapCeil 是按数量裁剪时历史采样数的下限和上限，
+// 避免极小或极大的 speedWindowMax 把采样数上限推到不合理的值。
+const speedSampleCapFloor = 64
+const speedSampleCapCeil = 4096
+
+// speedSampleCap 根据窗口时长推算历史采样数的上限，保证 speedSamples 的内存占用是 O(窗口/采样间隔)，
+// 不会因为调用方高频调用 IncrementBy 而无限增长。
+func speedSampleCap(windowMax time.Duration) int {
+	n := int(windowMax / minSpeedSampleInterval)
+	if n < speedSampleCapFloor {
+		n = speedSampleCapFloor
+	}
+	if n > speedSampleCapCeil {
+		n = speedSampleCapCeil
+	}
+	return n
+}
+
+// speedSample 是速度滑动窗口中的一个采样点：某个时刻的已完成工作单元数。
+type speedSample struct {
+	t       time.Time
+	current int
+}
+
+// SetSpeedWindow 配置速度/ETA 估算使用的滑动窗口：
+// min 是窗口被认为可信之前必须覆盖的最短时长，max 是历史采样保留的最长时长
+// （超出 max 的旧采样会被丢弃）。这让 ETA 能够响应任务中途的速度变化，
+// 而不是被开始阶段的一次性突发速度主导。
+func (pb *ProgressBar) SetSpeedWindow(min, max time.Duration) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.speedWindowMin = min
+	pb.speedWindowMax = max
+}
+
+// recordSpeedSampleLocked 记录一个新的 (当前时间, current) 采样点，
+// 并丢弃超出 speedWindowMax 的旧采样，保证历史缓冲区大小是 O(窗口/采样间隔)。
+// 调用方必须持有 pb.mu。
+func (pb *ProgressBar) recordSpeedSampleLocked() {
+	if pb.speedSamples == nil {
+		pb.speedSamples = list.New()
+	}
+	pb.speedSamples.PushBack(speedSample{t: time.Now(), current: pb.current})
+
+	windowMax := pb.speedWindowMax
+	if windowMax <= 0 {
+		windowMax = defaultSpeedWindowMax
+	}
+
+	// 至少保留两个采样点才能算出速度，其余超出窗口时长的旧采样予以丢弃。
+	for pb.speedSamples.Len() > 2 {
+		front := pb.speedSamples.Front()
+		back := pb.speedSamples.Back()
+		if back.Value.(speedSample).t.Sub(front.Value.(speedSample).t) <= windowMax {
+			break
+		}
+		pb.speedSamples.Remove(front)
+	}
+
+	// 按时间裁剪无法应对高频调用（大量采样落在同一个窗口内），
+	// 再按数量兜底裁剪，保证内存占用有硬上限。
+	if cap := speedSampleCap(windowMax); pb.speedSamples.Len() > cap {
+		for pb.speedSamples.Len() > cap {
+			pb.speedSamples.Remove(pb.speedSamples.Front())
+		}
+	}
+}
+
+// windowSpeedLocked 用滑动窗口内最早和最新的采样点计算速度：
+// (最新.current - 最早.current) / (最新.t - 最早.t)。
+// 当窗口跨度还不足 speedWindowMin，或窗口内 current 没有变化（任务停滞）时，
+// 返回 ok=false，调用方应据此展示 "Estimating..." 而不是一个虚假的速度。
+// 调用方必须持有 pb.mu。
+func (pb *ProgressBar) windowSpeedLocked() (speed float64, ok bool) {
+	if pb.speedSamples == nil || pb.speedSamples.Len() < 2 {
+		return 0, false
+	}
+
+	windowMin := pb.speedWindowMin
+	if windowMin <= 0 {
+		windowMin = defaultSpeedWindowMin
+	}
+
+	oldest := pb.speedSamples.Front().Value.(speedSample)
+	latest := pb.speedSamples.Back().Value.(speedSample)
+
+	elapsed := latest.t.Sub(oldest.t)
+	if elapsed < windowMin || elapsed <= 0 {
+		return 0, false
+	}
+
+	delta := latest.current - oldest.current
+	if delta == 0 {
+		return 0, false
+	}
+
+	return float64(delta) / elapsed.Seconds(), true
+}
@@ -0,0 +1,23 @@
+package progress_reporter
+
+import (
+	"io"
+	"testing"
+)
+
+func TestRecordSpeedSampleLockedBoundsHistoryByCount(t *testing.T) {
+	pb := NewProgressBarWithOptions("Speed Cap", 1_000_000, 10, OptionSetWriter(io.Discard))
+
+	const calls = 200_000
+	for i := 0; i < calls; i++ {
+		pb.IncrementBy(1)
+	}
+
+	pb.mu.Lock()
+	got := pb.speedSamples.Len()
+	pb.mu.Unlock()
+
+	if want := speedSampleCap(defaultSpeedWindowMax); got > want {
+		t.Errorf("expected speedSamples to be capped at %d, got %d after %d rapid increments", want, got, calls)
+	}
+}
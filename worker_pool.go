@@ -0,0 +1,71 @@
+package progress_reporter
+
+import "sync"
+
+// WorkerPool 是一个容量有限的 goroutine 池，把提交的任务与 ProgressReporter 的
+// 阶段耗时统计、ProgressBar 的进度展示自动关联起来，调用方不再需要手动在每个
+// 任务里穿插 StartKeyStageRecord/EndKeyStageRecord/Increment。
+type WorkerPool struct {
+	sem         chan struct{} // 容量等于池大小的信号量，限制同时运行的任务数
+	wg          sync.WaitGroup
+	releaseOnce sync.Once // 保证 Release 多次调用时只关闭一次 sem
+
+	pb *ProgressBar
+	pr *ProgressReporter
+}
+
+// NewWorkerPool 创建一个新的 WorkerPool。
+// size: 同时运行的任务数上限，小于等于 0 时按 1 处理。
+// pb: 每个任务成功完成后自动 Increment 的进度条。
+// pr: 记录每个任务所属阶段耗时的 ProgressReporter。
+func NewWorkerPool(size int, pb *ProgressBar, pr *ProgressReporter) *WorkerPool {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &WorkerPool{
+		sem: make(chan struct{}, size),
+		pb:  pb,
+		pr:  pr,
+	}
+}
+
+// Submit 提交一个任务，在池中尚有空闲名额时立即执行，否则阻塞直到有名额空出。
+// 调用方不需要预先知道任务总数：Submit 会先对 pb 调用 AddTotal(1)，
+// 所以可以在运行过程中动态发现并提交新任务。
+// stageName: 该任务所属的关键阶段名称，用于 pr 的耗时统计。
+// fn: 任务函数，返回 error 表示失败；失败的任务仍会被计入耗时，但不会推进 pb 的进度。
+func (p *WorkerPool) Submit(stageName string, fn func() error) {
+	p.pb.AddTotal(1)
+
+	p.wg.Add(1)
+	p.sem <- struct{}{}
+
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.sem }()
+
+		tok := p.pr.StartKeyStageRecord(stageName)
+		err := fn()
+		p.pr.EndKeyStageRecord(tok)
+
+		if err != nil {
+			p.pr.RecordKeyStageFailure(stageName)
+			return
+		}
+		p.pb.Increment()
+	}()
+}
+
+// Wait 阻塞直到所有已提交的任务都执行完毕。
+func (p *WorkerPool) Wait() {
+	p.wg.Wait()
+}
+
+// Release 释放 WorkerPool 持有的资源。应在 Wait 返回之后调用，
+// 调用之后不应再对该 WorkerPool 调用 Submit。Release 可以被安全地多次调用。
+func (p *WorkerPool) Release() {
+	p.releaseOnce.Do(func() {
+		close(p.sem)
+	})
+}
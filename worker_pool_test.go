@@ -0,0 +1,67 @@
+package progress_reporter
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestWorkerPoolSubmitAndWait(t *testing.T) {
+	pb := NewProgressBarWithOptions("Workers", 0, 10, OptionSetWriter(io.Discard))
+	pr := NewProgressReporter()
+	wp := NewWorkerPool(4, pb, pr)
+
+	const tasks = 20
+	const failEvery = 5
+	wantFailures := 0
+
+	for i := 0; i < tasks; i++ {
+		i := i
+		wp.Submit("work", func() error {
+			if i%failEvery == 0 {
+				return errors.New("boom")
+			}
+			return nil
+		})
+		if i%failEvery == 0 {
+			wantFailures++
+		}
+	}
+
+	wp.Wait()
+	wp.Release()
+
+	pb.mu.Lock()
+	total, current := pb.total, pb.current
+	pb.mu.Unlock()
+
+	if total != tasks {
+		t.Errorf("expected pb.total to reach %d, got %d", tasks, total)
+	}
+	if want := tasks - wantFailures; current != want {
+		t.Errorf("expected pb.current to only advance for successful tasks, got %d, want %d", current, want)
+	}
+
+	snapshot := pr.Snapshot()
+	if len(snapshot.Stages) != 1 || snapshot.Stages[0].Name != "work" {
+		t.Fatalf("expected one stage named 'work', got %+v", snapshot.Stages)
+	}
+	if got := snapshot.Stages[0].Count; got != tasks {
+		t.Errorf("expected %d recorded stage samples, got %d", tasks, got)
+	}
+	if got := snapshot.Stages[0].FailCount; got != wantFailures {
+		t.Errorf("expected %d recorded stage failures, got %d", wantFailures, got)
+	}
+}
+
+func TestWorkerPoolReleaseIsSafeToCallMultipleTimes(t *testing.T) {
+	pb := NewProgressBarWithOptions("Workers", 0, 10, OptionSetWriter(io.Discard))
+	pr := NewProgressReporter()
+	wp := NewWorkerPool(2, pb, pr)
+
+	wp.Submit("work", func() error { return nil })
+	wp.Wait()
+
+	wp.Release()
+	wp.Release() // 第二次调用不应该 panic（例如重复 close 一个 channel）
+}